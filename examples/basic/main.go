@@ -1,29 +1,557 @@
 package main
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"path"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
 )
 
+var timeZero = time.Time{}
+
+// reloadHeartbeatInterval is how often the /__go_reload SSE endpoint pings
+// idle clients to keep the connection alive through proxies and browsers.
+const reloadHeartbeatInterval = 15 * time.Second
+
+// buildCommit and buildTime are set at build time via:
+//
+//	go build -ldflags "-X main.buildCommit=$(git rev-parse HEAD) -X main.buildTime=$(date -u +%FT%TZ)"
+var (
+	buildCommit = "dev"
+	buildTime   = "unknown"
+)
+
+// startTime records process startup for uptime reporting.
+var startTime = time.Now()
+
 func main() {
 	// Parse command line flags
 	port := flag.String("port", "8080", "Port to listen on")
+	dir := flag.String("dir", "", "Directory of static files to serve (enables static file serving mode)")
+	staticPrefix := flag.String("static-prefix", "/assets", "URL path prefix under which static files are served")
+	browse := flag.Bool("browse", false, "Enable directory listing for the static file server")
+	logFormat := flag.String("log-format", "plain", "Request log format: plain or json")
+	shutdownTimeout := flag.Duration("shutdown-timeout", 5*time.Second, "Time to wait for in-flight requests to finish on shutdown")
 	flag.Parse()
 
+	mux := http.NewServeMux()
+
+	startupNonce := newNonce()
+	reloadBroker := newReloadBroker()
+
 	// Define a simple handler for the root path
-	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		fmt.Fprintf(w, "Hello from Go!")
-	})
+	mux.HandleFunc("/", rootHandler)
 
 	// Define a handler for the /test endpoint
-	http.HandleFunc("/test", func(w http.ResponseWriter, r *http.Request) {
-		fmt.Fprintf(w, "This is the /test endpoint from Go!")
+	mux.HandleFunc("/test", testHandler)
+
+	// Optionally mount a static file server, e.g. for serving Vite's build output.
+	if *dir != "" {
+		prefix := normalizePrefix(*staticPrefix)
+		mux.Handle(prefix, staticHandler(*dir, prefix, *browse))
+		log.Printf("Serving static files from %q at %s (browse=%v)", *dir, prefix, *browse)
+	}
+
+	// Live-reload endpoint: the Vite plugin subscribes to this and triggers a
+	// full page reload whenever it sees the startup nonce change, which
+	// happens whenever this binary is rebuilt and restarted.
+	mux.HandleFunc("/__go_reload", reloadHandler(startupNonce, reloadBroker))
+
+	// Runtime info, health, readiness, and Prometheus metrics endpoints.
+	m := newMetrics()
+	mux.HandleFunc("/healthz", healthzHandler)
+	mux.HandleFunc("/readyz", readyzHandler)
+	mux.HandleFunc("/debug/info", debugInfoHandler)
+	mux.HandleFunc("/metrics", m.handler())
+
+	handler := chain(mux, loggingMiddleware(*logFormat), m.middleware())
+
+	srv := &http.Server{
+		Addr:    ":" + *port,
+		Handler: handler,
+	}
+
+	go func() {
+		log.Printf("Server started on port %s", *port)
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("server error: %v", err)
+		}
+	}()
+
+	go watchForReloadSignal(reloadBroker)
+
+	waitForShutdown(srv, *shutdownTimeout)
+}
+
+// rootHandler handles requests to the root path.
+func rootHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Fprintf(w, "Hello from Go!")
+}
+
+// testHandler handles requests to the /test endpoint.
+func testHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Fprintf(w, "This is the /test endpoint from Go!")
+}
+
+// watchForReloadSignal broadcasts a "reload" event to every connected
+// /__go_reload client whenever this process receives SIGHUP, so the Vite
+// plugin (or an operator running `kill -HUP`) can force a browser refresh
+// without restarting the Go binary.
+func watchForReloadSignal(broker *reloadBroker) {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGHUP)
+	for range sigCh {
+		log.Print("Received SIGHUP, broadcasting reload")
+		broker.Broadcast("reload")
+	}
+}
+
+// waitForShutdown blocks until SIGINT or SIGTERM is received, then gives the
+// server up to timeout to finish in-flight requests before returning.
+func waitForShutdown(srv *http.Server, timeout time.Duration) {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	sig := <-sigCh
+	log.Printf("Received %s, shutting down (timeout %s)", sig, timeout)
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Printf("Graceful shutdown failed: %v", err)
+	}
+}
+
+// middleware wraps a handler to add cross-cutting behavior, e.g. logging.
+type middleware func(http.Handler) http.Handler
+
+// chain applies middlewares to h in order, so the first middleware listed
+// runs outermost.
+func chain(h http.Handler, middlewares ...middleware) http.Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		h = middlewares[i](h)
+	}
+	return h
+}
+
+// loggingMiddleware logs method, path, status code, duration, and remote
+// addr for every request, in either "plain" or "json" format.
+func loggingMiddleware(format string) middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			lrw := &loggingResponseWriter{ResponseWriter: w, status: http.StatusOK}
+
+			next.ServeHTTP(lrw, r)
+
+			duration := time.Since(start)
+			if format == "json" {
+				logEntryJSON(r, lrw.status, duration)
+			} else {
+				log.Printf("%s %s %d %s %s", r.Method, r.URL.Path, lrw.status, duration, r.RemoteAddr)
+			}
+		})
+	}
+}
+
+// loggingResponseWriter captures the status code written by the handler so
+// it can be included in the access log.
+type loggingResponseWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *loggingResponseWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Flush delegates to the wrapped ResponseWriter's Flush, if it implements
+// http.Flusher, so handlers that stream (e.g. SSE) keep working when wrapped
+// by this middleware.
+func (w *loggingResponseWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// jsonLogger writes access log lines with no date/time prefix so each line
+// is valid, standalone JSON.
+var jsonLogger = log.New(log.Writer(), "", 0)
+
+// logEntryJSON writes a single JSON-encoded access log line to the standard
+// logger's output (stderr by default).
+func logEntryJSON(r *http.Request, status int, duration time.Duration) {
+	entry := struct {
+		Method     string `json:"method"`
+		Path       string `json:"path"`
+		Status     int    `json:"status"`
+		DurationMS int64  `json:"duration_ms"`
+		RemoteAddr string `json:"remote_addr"`
+	}{
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		Status:     status,
+		DurationMS: duration.Milliseconds(),
+		RemoteAddr: r.RemoteAddr,
+	}
+	if data, err := json.Marshal(entry); err == nil {
+		jsonLogger.Println(string(data))
+	}
+}
+
+// newNonce generates a short random token that identifies this process
+// instance, so clients can tell a reconnect apart from a restart.
+func newNonce() string {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(buf)
+}
+
+// reloadBroker is a small pubsub hub that fans "reload" events out to every
+// connected /__go_reload client.
+type reloadBroker struct {
+	mu      sync.Mutex
+	clients map[chan string]struct{}
+}
+
+func newReloadBroker() *reloadBroker {
+	return &reloadBroker{clients: make(map[chan string]struct{})}
+}
+
+// Subscribe registers a new client channel and returns it along with an
+// unsubscribe function the caller must run when the client disconnects.
+func (b *reloadBroker) Subscribe() (chan string, func()) {
+	ch := make(chan string, 1)
+
+	b.mu.Lock()
+	b.clients[ch] = struct{}{}
+	b.mu.Unlock()
+
+	unsubscribe := func() {
+		b.mu.Lock()
+		delete(b.clients, ch)
+		b.mu.Unlock()
+		close(ch)
+	}
+	return ch, unsubscribe
+}
+
+// Broadcast sends event to every currently subscribed client, dropping it
+// for clients whose channel is still full rather than blocking.
+func (b *reloadBroker) Broadcast(event string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.clients {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// reloadHandler serves Server-Sent Events on /__go_reload. It sends the
+// process's startup nonce as soon as a client connects (so the Vite plugin
+// can detect a restart across reconnects), relays "reload" events broadcast
+// on broker (see watchForReloadSignal), and sends a heartbeat comment every
+// reloadHeartbeatInterval to keep the connection alive.
+func reloadHandler(startupNonce string, broker *reloadBroker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+
+		events, unsubscribe := broker.Subscribe()
+		defer unsubscribe()
+
+		fmt.Fprintf(w, "event: nonce\ndata: %s\n\n", startupNonce)
+		flusher.Flush()
+
+		heartbeat := time.NewTicker(reloadHeartbeatInterval)
+		defer heartbeat.Stop()
+
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case event := <-events:
+				fmt.Fprintf(w, "event: reload\ndata: %s\n\n", event)
+				flusher.Flush()
+			case <-heartbeat.C:
+				fmt.Fprint(w, ": ping\n\n")
+				flusher.Flush()
+			}
+		}
+	}
+}
+
+// normalizePrefix ensures prefix starts and ends with a single slash, e.g.
+// "assets" -> "/assets/" and "/assets" -> "/assets/".
+func normalizePrefix(prefix string) string {
+	if !strings.HasPrefix(prefix, "/") {
+		prefix = "/" + prefix
+	}
+	if !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	return prefix
+}
+
+// staticHandler serves files out of dir under prefix. Paths that look like
+// SPA client-side routes (see looksLikeNavigation) fall back to index.html;
+// anything else that's missing, such as a mistyped or stale asset URL,
+// 404s instead of masking the problem with the app shell. Directory
+// listing is only served when browse is true; otherwise a directory
+// request without an index.html results in a 404.
+func staticHandler(dir, prefix string, browse bool) http.Handler {
+	fs := http.Dir(dir)
+	fileServer := http.StripPrefix(prefix, http.FileServer(fs))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rel := strings.TrimPrefix(r.URL.Path, prefix)
+		if rel == "" {
+			rel = "/"
+		} else if !strings.HasPrefix(rel, "/") {
+			rel = "/" + rel
+		}
+
+		f, err := fs.Open(rel)
+		if err != nil {
+			if looksLikeNavigation(r, rel) {
+				serveIndexFallback(w, r, fs)
+				return
+			}
+			http.NotFound(w, r)
+			return
+		}
+		defer f.Close()
+
+		if info, statErr := f.Stat(); statErr == nil && info.IsDir() && !browse {
+			if !serveDirIndex(w, r, fs, rel) {
+				http.NotFound(w, r)
+			}
+			return
+		}
+
+		fileServer.ServeHTTP(w, r)
 	})
+}
+
+// looksLikeNavigation reports whether a missing path is likely an SPA
+// client-side route (and so should fall back to index.html) rather than a
+// missing asset (which should 404). A path is treated as navigation if its
+// last segment has no file extension, or if the client explicitly asked for
+// HTML.
+func looksLikeNavigation(r *http.Request, rel string) bool {
+	last := rel
+	if idx := strings.LastIndex(rel, "/"); idx != -1 {
+		last = rel[idx+1:]
+	}
+	if !strings.Contains(last, ".") {
+		return true
+	}
+	return strings.Contains(r.Header.Get("Accept"), "text/html")
+}
+
+// serveIndexFallback serves the static root's index.html, used for SPA
+// routes that don't map to a file on disk.
+func serveIndexFallback(w http.ResponseWriter, r *http.Request, fs http.FileSystem) bool {
+	index, err := fs.Open("/index.html")
+	if err != nil {
+		http.NotFound(w, r)
+		return false
+	}
+	defer index.Close()
+	seeker, ok := index.(io.ReadSeeker)
+	if !ok {
+		http.NotFound(w, r)
+		return false
+	}
+	http.ServeContent(w, r, "index.html", timeZero, seeker)
+	return true
+}
+
+// serveDirIndex serves dir/index.html when browsing is disabled, returning
+// false if no index.html exists so the caller can fall back to a 404.
+func serveDirIndex(w http.ResponseWriter, r *http.Request, fs http.FileSystem, dir string) bool {
+	index, err := fs.Open(path.Join(dir, "index.html"))
+	if err != nil {
+		return false
+	}
+	defer index.Close()
+	seeker, ok := index.(io.ReadSeeker)
+	if !ok {
+		return false
+	}
+	http.ServeContent(w, r, "index.html", timeZero, seeker)
+	return true
+}
+
+// healthzHandler reports whether the process is alive. It never fails once
+// the server is serving traffic.
+func healthzHandler(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, "ok")
+}
+
+// readyzHandler reports whether the process is ready to receive traffic.
+// This example server has no external dependencies to check, so it is ready
+// as soon as it is alive.
+func readyzHandler(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, "ok")
+}
+
+// debugInfo is the JSON payload served by /debug/info.
+type debugInfo struct {
+	UptimeSeconds float64 `json:"uptime_seconds"`
+	GoVersion     string  `json:"go_version"`
+	NumCPU        int     `json:"num_cpu"`
+	NumGoroutine  int     `json:"num_goroutine"`
+	Hostname      string  `json:"hostname"`
+	BuildCommit   string  `json:"build_commit"`
+	BuildTime     string  `json:"build_time"`
+	MemStats      struct {
+		AllocBytes      uint64 `json:"alloc_bytes"`
+		TotalAllocBytes uint64 `json:"total_alloc_bytes"`
+		SysBytes        uint64 `json:"sys_bytes"`
+		NumGC           uint32 `json:"num_gc"`
+	} `json:"mem_stats"`
+}
+
+// debugInfoHandler reports process and runtime information useful while
+// iterating with vite-plugin-go-watch.
+func debugInfoHandler(w http.ResponseWriter, r *http.Request) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	info := debugInfo{
+		UptimeSeconds: time.Since(startTime).Seconds(),
+		GoVersion:     runtime.Version(),
+		NumCPU:        runtime.NumCPU(),
+		NumGoroutine:  runtime.NumGoroutine(),
+		Hostname:      hostname,
+		BuildCommit:   buildCommit,
+		BuildTime:     buildTime,
+	}
+	info.MemStats.AllocBytes = mem.Alloc
+	info.MemStats.TotalAllocBytes = mem.TotalAlloc
+	info.MemStats.SysBytes = mem.Sys
+	info.MemStats.NumGC = mem.NumGC
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(info); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// durationBucketsSeconds are the upper bounds (in seconds) of the
+// request-duration histogram buckets exposed on /metrics.
+var durationBucketsSeconds = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// metrics tracks the counters and histogram backing the /metrics endpoint.
+type metrics struct {
+	requestsTotal uint64
+	inFlight      int64
+
+	mu            sync.Mutex
+	bucketCounts  []uint64
+	durationSum   float64
+	durationCount uint64
+}
+
+func newMetrics() *metrics {
+	return &metrics{bucketCounts: make([]uint64, len(durationBucketsSeconds))}
+}
+
+// middleware counts in-flight requests, total requests, and request
+// duration for every request passing through it.
+func (m *metrics) middleware() middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt64(&m.inFlight, 1)
+			defer atomic.AddInt64(&m.inFlight, -1)
+
+			start := time.Now()
+			next.ServeHTTP(w, r)
+			m.observe(time.Since(start).Seconds())
+		})
+	}
+}
+
+// observe records a single request's duration into the histogram and
+// increments the request counter.
+func (m *metrics) observe(seconds float64) {
+	atomic.AddUint64(&m.requestsTotal, 1)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.durationSum += seconds
+	m.durationCount++
+	for i, bound := range durationBucketsSeconds {
+		if seconds <= bound {
+			m.bucketCounts[i]++
+			break
+		}
+	}
+}
+
+// handler renders the collected metrics in Prometheus text exposition
+// format.
+func (m *metrics) handler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		m.mu.Lock()
+		buckets := append([]uint64(nil), m.bucketCounts...)
+		durationSum := m.durationSum
+		durationCount := m.durationCount
+		m.mu.Unlock()
+
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+
+		fmt.Fprintln(w, "# HELP go_watch_requests_total Total number of HTTP requests handled.")
+		fmt.Fprintln(w, "# TYPE go_watch_requests_total counter")
+		fmt.Fprintf(w, "go_watch_requests_total %d\n", atomic.LoadUint64(&m.requestsTotal))
+
+		fmt.Fprintln(w, "# HELP go_watch_in_flight_requests Number of HTTP requests currently being served.")
+		fmt.Fprintln(w, "# TYPE go_watch_in_flight_requests gauge")
+		fmt.Fprintf(w, "go_watch_in_flight_requests %d\n", atomic.LoadInt64(&m.inFlight))
 
-	// Start the server
-	addr := ":" + *port
-	log.Printf("Server started on port %s", *port)
-	log.Fatal(http.ListenAndServe(addr, nil))
+		fmt.Fprintln(w, "# HELP go_watch_request_duration_seconds HTTP request duration in seconds.")
+		fmt.Fprintln(w, "# TYPE go_watch_request_duration_seconds histogram")
+		var cumulative uint64
+		for i, bound := range durationBucketsSeconds {
+			cumulative += buckets[i]
+			fmt.Fprintf(w, "go_watch_request_duration_seconds_bucket{le=\"%g\"} %d\n", bound, cumulative)
+		}
+		fmt.Fprintf(w, "go_watch_request_duration_seconds_bucket{le=\"+Inf\"} %d\n", durationCount)
+		fmt.Fprintf(w, "go_watch_request_duration_seconds_sum %g\n", durationSum)
+		fmt.Fprintf(w, "go_watch_request_duration_seconds_count %d\n", durationCount)
+	}
 }
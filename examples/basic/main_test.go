@@ -0,0 +1,161 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRootHandler(t *testing.T) {
+	rec := httptest.NewRecorder()
+	rootHandler(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if got, want := rec.Body.String(), "Hello from Go!"; got != want {
+		t.Errorf("body = %q, want %q", got, want)
+	}
+}
+
+func TestTestHandler(t *testing.T) {
+	rec := httptest.NewRecorder()
+	testHandler(rec, httptest.NewRequest(http.MethodGet, "/test", nil))
+
+	if got, want := rec.Body.String(), "This is the /test endpoint from Go!"; got != want {
+		t.Errorf("body = %q, want %q", got, want)
+	}
+}
+
+func TestStaticHandlerServesFile(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "app.js"), "console.log('hi')")
+
+	handler := staticHandler(dir, "/assets/", false)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/assets/app.js", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if got, want := rec.Body.String(), "console.log('hi')"; got != want {
+		t.Errorf("body = %q, want %q", got, want)
+	}
+}
+
+func TestStaticHandlerSPAFallback(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "index.html"), "<html>app shell</html>")
+
+	handler := staticHandler(dir, "/assets/", false)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/assets/client/route", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if got, want := rec.Body.String(), "<html>app shell</html>"; got != want {
+		t.Errorf("body = %q, want %q (expected index.html fallback)", got, want)
+	}
+}
+
+// TestStaticHandlerMissingAssetNotFalledBack guards against a regression
+// where any missing path, including a mistyped or stale asset URL, fell
+// back to index.html instead of 404ing.
+func TestStaticHandlerMissingAssetNotFalledBack(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "index.html"), "<html>app shell</html>")
+
+	handler := staticHandler(dir, "/assets/", false)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/assets/app.xyz.js", nil))
+
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want %d (missing asset should 404, not fall back to index.html)", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestStaticHandlerDirectoryListingDisabledByDefault(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(dir, "sub", "file.txt"), "data")
+
+	handler := staticHandler(dir, "/assets/", false)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/assets/sub/", nil))
+
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want %d (directory listing should be disabled)", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestStaticHandlerDirectoryListingEnabled(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(dir, "sub", "file.txt"), "data")
+
+	handler := staticHandler(dir, "/assets/", true)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/assets/sub/", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d (directory listing should be enabled)", rec.Code, http.StatusOK)
+	}
+}
+
+// TestLoggingResponseWriterFlush guards against a regression where
+// loggingResponseWriter embedded http.ResponseWriter without forwarding
+// Flush, which silently broke streaming handlers like /__go_reload.
+func TestLoggingResponseWriterFlush(t *testing.T) {
+	rec := httptest.NewRecorder()
+	lrw := &loggingResponseWriter{ResponseWriter: rec, status: http.StatusOK}
+
+	flusher, ok := interface{}(lrw).(http.Flusher)
+	if !ok {
+		t.Fatal("loggingResponseWriter does not implement http.Flusher")
+	}
+	flusher.Flush()
+
+	if !rec.Flushed {
+		t.Error("Flush() did not reach the underlying ResponseWriter")
+	}
+}
+
+// TestMetricsHistogramIsCumulative guards against a regression where
+// metrics.observe incremented every bucket an observation fell into, which
+// combined with handler's cumulative sum produced a double-counted
+// histogram.
+func TestMetricsHistogramIsCumulative(t *testing.T) {
+	m := newMetrics()
+	m.observe(0.001) // falls into the smallest (0.005s) bucket
+
+	rec := httptest.NewRecorder()
+	m.handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	body := rec.Body.String()
+	wantSmallest := "go_watch_request_duration_seconds_bucket{le=\"0.005\"} 1\n"
+	wantLargest := "go_watch_request_duration_seconds_bucket{le=\"10\"} 1\n"
+	wantInf := "go_watch_request_duration_seconds_bucket{le=\"+Inf\"} 1\n"
+
+	for _, want := range []string{wantSmallest, wantLargest, wantInf} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics output missing %q; got:\n%s", want, body)
+		}
+	}
+}
+
+func writeFile(t *testing.T, path, contents string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}